@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -25,23 +26,42 @@ import (
 func BulkInsert(db *gorm.DB, objects []interface{}, chunkSize int, excludeColumns ...string) error {
 	// Split records with specified size not to exceed Database parameter limit
 	for _, objSet := range splitObjects(objects, chunkSize) {
-		if _, err := insertObjSet(db, false, objSet, excludeColumns...); err != nil {
+		if _, err := insertObjSet(db, nil, objSet, nil, excludeColumns...); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func BulkInsertIgnore(db *gorm.DB, objects []interface{}, chunkSize int, excludeColumns ...string) (rows_affected int64, err error) {
+// BulkInsertIgnore inserts records while silently skipping any that conflict
+// with an existing row. It passes no conflict target: on PostgreSQL this emits
+// ON CONFLICT DO NOTHING, which skips a row that violates any unique
+// constraint, and on mssql it returns an error, since the MERGE used there
+// needs an explicit target. See BulkInsertIgnoreWithConflictTarget to specify
+// the target column(s).
+func BulkInsertIgnore(db *gorm.DB, objects []interface{}, chunkSize int, excludeColumns ...string) (rowsAffected int64, err error) {
+	return BulkInsertIgnoreWithConflictTarget(db, objects, chunkSize, nil, excludeColumns...)
+}
+
+// BulkInsertIgnoreWithConflictTarget behaves like BulkInsertIgnore, but lets
+// the caller specify the column(s) that define the conflict instead of
+// defaulting to the primary key.
+func BulkInsertIgnoreWithConflictTarget(db *gorm.DB, objects []interface{}, chunkSize int, conflictTarget []string, excludeColumns ...string) (rowsAffected int64, err error) {
+	strategy, err := conflictStrategyFor(db.Dialect().GetName())
+	if err != nil {
+		return 0, err
+	}
 	for _, objSet := range splitObjects(objects, chunkSize) {
-		if rows, err := insertObjSet(db, true, objSet, excludeColumns...); err == nil {
-			rows_affected += rows
+		rows, err := insertObjSet(db, strategy, objSet, conflictTarget, excludeColumns...)
+		if err != nil {
+			return rowsAffected, err
 		}
+		rowsAffected += rows
 	}
-	return
+	return rowsAffected, nil
 }
 
-func insertObjSet(db *gorm.DB, ignore bool, objects []interface{}, excludeColumns ...string) (rows_affected int64, err error) {
+func insertObjSet(db *gorm.DB, conflict ConflictStrategy, objects []interface{}, conflictTarget []string, excludeColumns ...string) (rows_affected int64, err error) {
 	if len(objects) == 0 {
 		return
 	}
@@ -100,21 +120,35 @@ func insertObjSet(db *gorm.DB, ignore bool, objects []interface{}, excludeColumn
 		}
 		insertOption = strVal
 	}
-	strignore := ""
-	switch db.Dialect().GetName() {
-	case "mysql":
-		strignore = "IGNORE"
-	case "postgres":
-		insertOption += " ON CONFLICT IGNORE"
+
+	query := ""
+	if conflict != nil {
+		clause, err := conflict.Build(mainScope, dbColumns, placeholders, conflictTarget)
+		if err != nil {
+			return 0, err
+		}
+		if clause.Statement != "" {
+			query = clause.Statement
+		} else {
+			query = fmt.Sprintf("INSERT %s INTO %s (%s) VALUES %s %s %s",
+				clause.Modifier,
+				mainScope.QuotedTableName(),
+				strings.Join(dbColumns, ", "),
+				strings.Join(placeholders, ", "),
+				clause.Suffix,
+				insertOption,
+			)
+		}
+	} else {
+		query = fmt.Sprintf("INSERT INTO %s (%s) VALUES %s %s",
+			mainScope.QuotedTableName(),
+			strings.Join(dbColumns, ", "),
+			strings.Join(placeholders, ", "),
+			insertOption,
+		)
 	}
 
-	mainScope.Raw(fmt.Sprintf("INSERT %s INTO %s (%s) VALUES %s %s",
-		strignore,
-		mainScope.QuotedTableName(),
-		strings.Join(dbColumns, ", "),
-		strings.Join(placeholders, ", "),
-		insertOption,
-	))
+	mainScope.Raw(query)
 
 	result := db.Exec(mainScope.SQL, mainScope.SQLVars...)
 	return result.RowsAffected, result.Error
@@ -166,3 +200,38 @@ func fieldIsAutoIncrement(field *gorm.Field) bool {
 func fieldIsPrimaryAndBlank(field *gorm.Field) bool {
 	return field.IsPrimaryKey && field.IsBlank
 }
+
+// splitObjects splits objects into chunks of at most chunkSize elements each,
+// so a single INSERT never embeds more than chunkSize rows worth of variables.
+func splitObjects(objects []interface{}, chunkSize int) [][]interface{} {
+	var chunks [][]interface{}
+	for chunkSize < len(objects) {
+		objects, chunks = objects[chunkSize:], append(chunks, objects[0:chunkSize:chunkSize])
+	}
+	if len(objects) > 0 {
+		chunks = append(chunks, objects)
+	}
+	return chunks
+}
+
+// sortedKeys returns the keys of attrs in sorted order, so the column list
+// and the positional placeholders built alongside it always line up the same
+// way across objects and across calls.
+func sortedKeys(attrs map[string]interface{}) []string {
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// containString reports whether slice contains str.
+func containString(slice []string, str string) bool {
+	for _, s := range slice {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package gormbulk
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestBackfillSequentialIDs_SkipsExplicitPrimaryKeys(t *testing.T) {
+	explicit := &bulkTestRecord{ID: 99, Name: "explicit"}
+	blank := &bulkTestRecord{Name: "blank"}
+	objects := []interface{}{explicit, blank}
+
+	if err := backfillSequentialIDs(objects, "id", 5, 2); err != nil {
+		t.Fatalf("backfillSequentialIDs: %v", err)
+	}
+
+	if explicit.ID != 99 {
+		t.Errorf("explicit.ID = %d, want unchanged 99", explicit.ID)
+	}
+	if blank.ID != 5 {
+		t.Errorf("blank.ID = %d, want 5", blank.ID)
+	}
+}
+
+func TestBackfillSequentialIDs_AssignsContiguousRange(t *testing.T) {
+	a := &bulkTestRecord{Name: "a"}
+	b := &bulkTestRecord{Name: "b"}
+	c := &bulkTestRecord{Name: "c"}
+	objects := []interface{}{a, b, c}
+
+	if err := backfillSequentialIDs(objects, "id", 10, 3); err != nil {
+		t.Fatalf("backfillSequentialIDs: %v", err)
+	}
+
+	if a.ID != 10 || b.ID != 11 || c.ID != 12 {
+		t.Errorf("IDs = %d, %d, %d, want 10, 11, 12", a.ID, b.ID, c.ID)
+	}
+}
+
+func TestBackfillSequentialIDs_UnaddressableFieldErrors(t *testing.T) {
+	// A struct value, rather than a pointer, can't have its field set through
+	// gorm's Field.Set - this is what a real BackfillError wraps.
+	objects := []interface{}{bulkTestRecord{Name: "not addressable"}}
+
+	err := backfillSequentialIDs(objects, "id", 1, 1)
+	if err == nil {
+		t.Fatal("expected an error backfilling into an unaddressable struct value")
+	}
+}
+
+func TestBackfillError_PreservesRowsAffectedAndUnwraps(t *testing.T) {
+	// Exercise the same failure that backfillSequentialIDs surfaces, wrapped
+	// the way returningObjSet reports it, so RowsAffected reflects rows that
+	// were actually committed even though the backfill itself failed.
+	err := backfillSequentialIDs([]interface{}{bulkTestRecord{Name: "x"}}, "id", 1, 1)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	wrapped := &BackfillError{RowsAffected: 1, Err: err}
+	if wrapped.RowsAffected != 1 {
+		t.Errorf("RowsAffected = %d, want 1", wrapped.RowsAffected)
+	}
+	if !errors.Is(wrapped, err) {
+		t.Errorf("expected BackfillError to unwrap to the underlying error")
+	}
+}
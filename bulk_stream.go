@@ -0,0 +1,148 @@
+package gormbulk
+
+import (
+	"context"
+
+	"github.com/jinzhu/gorm"
+)
+
+// BulkInsertStream consumes objects from ch and flushes each accumulated chunk
+// of chunkSize as a single multi-row INSERT, so callers ingesting large
+// datasets (e.g. from a file or HTTP stream) don't need to materialize the
+// whole slice in memory before calling BulkInsert. The final, possibly
+// shorter, chunk is flushed once ch is closed.
+func BulkInsertStream(db *gorm.DB, ch <-chan interface{}, chunkSize int, excludeColumns ...string) (int64, error) {
+	return BulkInsertStreamContext(context.Background(), db, ch, chunkSize, excludeColumns...)
+}
+
+// BulkInsertStreamContext behaves like BulkInsertStream, but stops consuming
+// from ch as soon as ctx is done, returning ctx.Err() alongside the rows
+// inserted so far. Cancellation is checked before each object is read from
+// ch, not while a chunk's INSERT runs: a chunk that has already started
+// executing is allowed to finish, and objects accumulated but not yet flushed
+// are not inserted.
+func BulkInsertStreamContext(ctx context.Context, db *gorm.DB, ch <-chan interface{}, chunkSize int, excludeColumns ...string) (int64, error) {
+	var rowsAffected int64
+	chunk := make([]interface{}, 0, chunkSize)
+
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		rows, err := insertObjSet(db, nil, chunk, nil, excludeColumns...)
+		rowsAffected += rows
+		chunk = chunk[:0]
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return rowsAffected, ctx.Err()
+		case obj, ok := <-ch:
+			if !ok {
+				return rowsAffected, flush()
+			}
+			chunk = append(chunk, obj)
+			if len(chunk) == chunkSize {
+				if err := flush(); err != nil {
+					return rowsAffected, err
+				}
+			}
+		}
+	}
+}
+
+// BulkInsertStreamPipelined behaves like BulkInsertStreamContext, but executes
+// the INSERT for chunk N concurrently with accumulating chunk N+1 from ch,
+// hiding the INSERT's network round-trip behind the time spent reading the
+// next chunk. pipelineDepth bounds how many accumulated chunks may be queued
+// for execution before accumulation blocks waiting for the inserter to catch
+// up; it is forced to at least 1.
+func BulkInsertStreamPipelined(ctx context.Context, db *gorm.DB, ch <-chan interface{}, chunkSize, pipelineDepth int, excludeColumns ...string) (int64, error) {
+	return pipelinedStream(ctx, ch, chunkSize, pipelineDepth, func(chunk []interface{}) (int64, error) {
+		return insertObjSet(db, nil, chunk, nil, excludeColumns...)
+	})
+}
+
+// pipelinedStream holds the accumulate/execute plumbing behind
+// BulkInsertStreamPipelined, with the actual chunk insert factored out behind
+// insert so it can be exercised in tests without a real *gorm.DB.
+func pipelinedStream(ctx context.Context, ch <-chan interface{}, chunkSize, pipelineDepth int, insert func(chunk []interface{}) (int64, error)) (int64, error) {
+	if pipelineDepth < 1 {
+		pipelineDepth = 1
+	}
+
+	chunks := make(chan []interface{}, pipelineDepth)
+	errs := make(chan error, 1)
+	done := make(chan struct{})
+
+	var rowsAffected int64
+	go func() {
+		defer close(done)
+		for chunk := range chunks {
+			rows, err := insert(chunk)
+			rowsAffected += rows
+			if err != nil {
+				select {
+				case errs <- err:
+				default:
+				}
+				return
+			}
+		}
+	}()
+
+	// sendChunk queues chunk for execution, but never blocks forever on a full
+	// chunks buffer: if the inserter goroutine has already failed and stopped
+	// draining chunks, errs will have a value waiting and this returns that
+	// error instead of hanging.
+	sendChunk := func(chunk []interface{}) error {
+		select {
+		case chunks <- chunk:
+			return nil
+		case err := <-errs:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	accumulate := func() error {
+		chunk := make([]interface{}, 0, chunkSize)
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case err := <-errs:
+				return err
+			case obj, ok := <-ch:
+				if !ok {
+					if len(chunk) > 0 {
+						return sendChunk(chunk)
+					}
+					return nil
+				}
+				chunk = append(chunk, obj)
+				if len(chunk) == chunkSize {
+					if err := sendChunk(chunk); err != nil {
+						return err
+					}
+					chunk = make([]interface{}, 0, chunkSize)
+				}
+			}
+		}
+	}
+
+	err := accumulate()
+	close(chunks)
+	<-done
+
+	if err == nil {
+		select {
+		case err = <-errs:
+		default:
+		}
+	}
+	return rowsAffected, err
+}
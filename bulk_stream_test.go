@@ -0,0 +1,93 @@
+package gormbulk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// runWithTimeout fails the test instead of hanging forever if fn deadlocks,
+// which is the failure mode a broken pipelinedStream exhibits.
+func runWithTimeout(t *testing.T, timeout time.Duration, fn func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn()
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		t.Fatal("test timed out, pipelinedStream likely deadlocked")
+	}
+}
+
+func TestPipelinedStream_FinalChunkDoesNotBlockAfterError(t *testing.T) {
+	ch := make(chan interface{}, 100)
+	for i := 0; i < 20; i++ {
+		ch <- i
+	}
+	close(ch)
+
+	wantErr := errors.New("boom")
+	calls := 0
+	insert := func(chunk []interface{}) (int64, error) {
+		calls++
+		if calls == 1 {
+			return 0, wantErr
+		}
+		return int64(len(chunk)), nil
+	}
+
+	runWithTimeout(t, 2*time.Second, func() {
+		// A pipelineDepth smaller than the number of chunks ch produces means
+		// several chunks - including the final, short one - queue up behind
+		// the first, failing insert.
+		_, err := pipelinedStream(context.Background(), ch, 3, 1, insert)
+		if !errors.Is(err, wantErr) {
+			t.Errorf("got err %v, want %v", err, wantErr)
+		}
+	})
+}
+
+func TestPipelinedStream_Success(t *testing.T) {
+	ch := make(chan interface{}, 10)
+	for i := 0; i < 7; i++ {
+		ch <- i
+	}
+	close(ch)
+
+	insert := func(chunk []interface{}) (int64, error) {
+		return int64(len(chunk)), nil
+	}
+
+	var rows int64
+	runWithTimeout(t, 2*time.Second, func() {
+		var err error
+		rows, err = pipelinedStream(context.Background(), ch, 3, 2, insert)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if rows != 7 {
+		t.Errorf("rowsAffected = %d, want 7", rows)
+	}
+}
+
+func TestPipelinedStream_ContextCancellation(t *testing.T) {
+	ch := make(chan interface{})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	insert := func(chunk []interface{}) (int64, error) {
+		return int64(len(chunk)), nil
+	}
+
+	runWithTimeout(t, 2*time.Second, func() {
+		_, err := pipelinedStream(ctx, ch, 3, 1, insert)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("got err %v, want context.Canceled", err)
+		}
+	})
+}
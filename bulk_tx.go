@@ -0,0 +1,185 @@
+package gormbulk
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+)
+
+// BulkOptions controls how BulkInsertTx executes.
+type BulkOptions struct {
+	// Transactional wraps all chunks in a single db.Begin()/Commit() transaction,
+	// so a mid-way failure leaves no rows inserted. When false, each chunk runs
+	// directly on db, matching BulkInsert's behavior.
+	Transactional bool
+
+	// MaxRetries is the number of additional attempts made for a chunk that
+	// fails with a transient error (a deadlock or serialization failure), with
+	// exponential backoff between attempts. Zero means no retries.
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry; it doubles after each
+	// subsequent attempt. Defaults to 100ms when zero.
+	RetryBackoff time.Duration
+
+	// ContinueOnError records a failing chunk's row indexes and error in the
+	// returned Result instead of aborting the insert. It is incompatible with
+	// Transactional, since a transaction must be rolled back as a whole.
+	ContinueOnError bool
+}
+
+// Result reports the outcome of a BulkInsertTx call.
+type Result struct {
+	RowsAffected int64
+
+	// Failures holds one entry per chunk that could not be inserted. It is
+	// only populated when BulkOptions.ContinueOnError is set; otherwise the
+	// first chunk error is returned directly and Result is incomplete.
+	Failures []ChunkFailure
+}
+
+// ChunkFailure records a chunk that failed to insert under ContinueOnError.
+type ChunkFailure struct {
+	// RowIndexes are the positions, in the original objects slice passed to
+	// BulkInsertTx, of the rows that made up the failing chunk.
+	RowIndexes []int
+	Err        error
+}
+
+// BulkInsertTx behaves like BulkInsert, but runs every chunk according to opts:
+// optionally inside a single transaction, retrying transient failures with
+// backoff, and optionally continuing past a failed chunk instead of aborting.
+func BulkInsertTx(db *gorm.DB, objects []interface{}, chunkSize int, opts BulkOptions, excludeColumns ...string) (Result, error) {
+	var result Result
+
+	exec := db
+	if opts.Transactional {
+		exec = db.Begin()
+		if exec.Error != nil {
+			return result, exec.Error
+		}
+	}
+
+	offset := 0
+	for _, objSet := range splitObjects(objects, chunkSize) {
+		rows, err := insertObjSetWithRetry(exec, opts, objSet, excludeColumns...)
+		if err != nil {
+			if opts.ContinueOnError && !opts.Transactional {
+				indexes := make([]int, len(objSet))
+				for i := range objSet {
+					indexes[i] = offset + i
+				}
+				result.Failures = append(result.Failures, ChunkFailure{RowIndexes: indexes, Err: err})
+			} else {
+				if opts.Transactional {
+					exec.Rollback()
+					// The rollback undoes every chunk committed so far in
+					// this transaction, so none of the rows tallied above
+					// actually made it to the database.
+					result.RowsAffected = 0
+				}
+				return result, err
+			}
+		} else {
+			result.RowsAffected += rows
+		}
+		offset += len(objSet)
+	}
+
+	if opts.Transactional {
+		if err := exec.Commit().Error; err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// bulkInsertTxSavepoint is the name used for the savepoint taken before each
+// chunk attempt when BulkOptions.Transactional is set. A plain retry of the
+// INSERT on the same transaction doesn't work on PostgreSQL: once a statement
+// errors, the whole transaction is aborted and every subsequent statement -
+// including the retry - fails with "current transaction is aborted" instead
+// of actually re-running. Rolling back to a savepoint clears that aborted
+// state without discarding the chunks already committed earlier in the
+// transaction.
+const bulkInsertTxSavepoint = "gormbulk_chunk"
+
+func insertObjSetWithRetry(db *gorm.DB, opts BulkOptions, objSet []interface{}, excludeColumns ...string) (int64, error) {
+	var rollbackToSavepoint func() error
+	if opts.Transactional {
+		if err := db.Exec(fmt.Sprintf("SAVEPOINT %s", bulkInsertTxSavepoint)).Error; err != nil {
+			return 0, err
+		}
+		rollbackToSavepoint = func() error {
+			return db.Exec(fmt.Sprintf("ROLLBACK TO SAVEPOINT %s", bulkInsertTxSavepoint)).Error
+		}
+	}
+
+	rows, err := retryChunk(opts, func() (int64, error) {
+		return insertObjSet(db, nil, objSet, nil, excludeColumns...)
+	}, rollbackToSavepoint)
+
+	if opts.Transactional && err == nil {
+		if rerr := db.Exec(fmt.Sprintf("RELEASE SAVEPOINT %s", bulkInsertTxSavepoint)).Error; rerr != nil {
+			return rows, rerr
+		}
+	}
+	return rows, err
+}
+
+// retryChunk runs insert, retrying up to opts.MaxRetries additional times
+// with exponential backoff (starting at opts.RetryBackoff, or 100ms if unset)
+// as long as the failure looks transient per isTransientError. rollback, if
+// non-nil, is called before each retry to undo whatever partial state the
+// failed attempt left behind (a SAVEPOINT rollback under BulkOptions.Transactional).
+// Extracted from insertObjSetWithRetry so the retry/backoff/error-classification
+// logic can be tested against a fake insert function, without a live database.
+func retryChunk(opts BulkOptions, insert func() (int64, error), rollback func() error) (int64, error) {
+	backoff := opts.RetryBackoff
+	if backoff <= 0 {
+		backoff = 100 * time.Millisecond
+	}
+
+	var rows int64
+	var err error
+	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+		rows, err = insert()
+		if err == nil {
+			break
+		}
+		if !isTransientError(err) || attempt == opts.MaxRetries {
+			break
+		}
+		if rollback != nil {
+			if rerr := rollback(); rerr != nil {
+				return rows, rerr
+			}
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+	return rows, err
+}
+
+// isTransientError reports whether err looks like a retryable deadlock or
+// serialization failure, using the driver's typed error rather than
+// substring-matching the formatted message (lib/pq, in particular, never
+// embeds the SQLSTATE code in Error.Error()'s text): MySQL error 1213
+// (ER_LOCK_DEADLOCK) or PostgreSQL SQLSTATE 40001 (serialization_failure).
+func isTransientError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == 1213
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "40001"
+	}
+
+	return false
+}
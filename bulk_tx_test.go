@@ -0,0 +1,114 @@
+package gormbulk
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestRetryChunk_RetriesTransientErrorThenSucceeds(t *testing.T) {
+	attempts := 0
+	rollbacks := 0
+	opts := BulkOptions{MaxRetries: 2, RetryBackoff: time.Millisecond}
+
+	rows, err := retryChunk(opts, func() (int64, error) {
+		attempts++
+		if attempts < 3 {
+			return 0, &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+		}
+		return 5, nil
+	}, func() error {
+		rollbacks++
+		return nil
+	})
+
+	if err != nil {
+		t.Fatalf("retryChunk: %v", err)
+	}
+	if rows != 5 {
+		t.Errorf("rows = %d, want 5", rows)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+	if rollbacks != 2 {
+		t.Errorf("rollbacks = %d, want 2", rollbacks)
+	}
+}
+
+func TestRetryChunk_GivesUpAfterMaxRetries(t *testing.T) {
+	attempts := 0
+	opts := BulkOptions{MaxRetries: 2, RetryBackoff: time.Millisecond}
+
+	_, err := retryChunk(opts, func() (int64, error) {
+		attempts++
+		return 0, &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}
+	}, func() error { return nil })
+
+	if err == nil {
+		t.Fatal("expected the final transient error to be returned")
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3 (1 initial + 2 retries)", attempts)
+	}
+}
+
+func TestRetryChunk_NonTransientErrorIsNotRetried(t *testing.T) {
+	attempts := 0
+	opts := BulkOptions{MaxRetries: 2, RetryBackoff: time.Millisecond}
+
+	_, err := retryChunk(opts, func() (int64, error) {
+		attempts++
+		return 0, errors.New("syntax error")
+	}, func() error { return nil })
+
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1 (non-transient errors should not be retried)", attempts)
+	}
+}
+
+func TestBulkInsertTx_ContinueOnErrorRecordsRowIndexes(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.Exec("CREATE UNIQUE INDEX ux_bulk_test_records_name ON bulk_test_records(name)").Error; err != nil {
+		t.Fatalf("failed to create unique index: %v", err)
+	}
+
+	objects := []interface{}{
+		&bulkTestRecord{Name: "a"},
+		&bulkTestRecord{Name: "a"}, // duplicate name, fails as its own chunk
+		&bulkTestRecord{Name: "c"},
+	}
+
+	opts := BulkOptions{ContinueOnError: true}
+	result, err := BulkInsertTx(db, objects, 1, opts)
+	if err != nil {
+		t.Fatalf("BulkInsertTx: %v", err)
+	}
+
+	if len(result.Failures) != 1 {
+		t.Fatalf("Failures = %+v, want exactly one failure", result.Failures)
+	}
+	if want := []int{1}; !equalInts(result.Failures[0].RowIndexes, want) {
+		t.Errorf("RowIndexes = %v, want %v", result.Failures[0].RowIndexes, want)
+	}
+	if result.RowsAffected != 2 {
+		t.Errorf("RowsAffected = %d, want 2", result.RowsAffected)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package gormbulk
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// BulkUpsert executes the query to insert multiple records at once, updating
+// [updateColumns] on any record that already exists. On PostgreSQL and SQLite
+// the conflict is detected on the table's primary key; on MySQL,
+// ON DUPLICATE KEY UPDATE fires on a conflict with any primary or unique key.
+//
+// [objects], [chunkSize] and [excludeColumns] behave the same way as in BulkInsert.
+//
+// [updateColumns] lists the columns to update when a conflict occurs. If empty,
+// every inserted column except the primary key is updated.
+//
+// Supported dialects are mysql, postgres and sqlite3. Other dialects return an error.
+func BulkUpsert(db *gorm.DB, objects []interface{}, chunkSize int, updateColumns []string, excludeColumns ...string) (int64, error) {
+	return BulkUpsertWithConflictTarget(db, objects, chunkSize, updateColumns, nil, excludeColumns...)
+}
+
+// BulkUpsertWithConflictTarget behaves like BulkUpsert, but lets the caller specify
+// which column(s) define the conflict instead of defaulting to the primary key.
+// This is required on PostgreSQL and SQLite when the upsert should be keyed off a
+// unique index rather than the primary key. On MySQL conflictTarget is not part
+// of the statement; it only decides which columns are left out of the default
+// updateColumns.
+func BulkUpsertWithConflictTarget(db *gorm.DB, objects []interface{}, chunkSize int, updateColumns []string, conflictTarget []string, excludeColumns ...string) (int64, error) {
+	var rowsAffected int64
+	for _, objSet := range splitObjects(objects, chunkSize) {
+		rows, err := upsertObjSet(db, objSet, updateColumns, conflictTarget, excludeColumns...)
+		if err != nil {
+			return rowsAffected, err
+		}
+		rowsAffected += rows
+	}
+	return rowsAffected, nil
+}
+
+func upsertObjSet(db *gorm.DB, objects []interface{}, updateColumns []string, conflictTarget []string, excludeColumns ...string) (rowsAffected int64, err error) {
+	if len(objects) == 0 {
+		return
+	}
+
+	firstAttrs, err := extractMapValue(objects[0], excludeColumns)
+	if err != nil {
+		return
+	}
+
+	attrSize := len(firstAttrs)
+
+	mainScope := db.NewScope(objects[0])
+	placeholders := make([]string, 0, attrSize)
+
+	keys := sortedKeys(firstAttrs)
+	dbColumns := make([]string, 0, attrSize)
+	for _, key := range keys {
+		dbColumns = append(dbColumns, mainScope.Quote(key))
+	}
+
+	for _, obj := range objects {
+		objAttrs, err := extractMapValue(obj, excludeColumns)
+		if err != nil {
+			return 0, err
+		}
+
+		if len(objAttrs) != attrSize {
+			err = errors.New("attribute sizes are inconsistent")
+			return 0, err
+		}
+
+		scope := db.NewScope(obj)
+
+		variables := make([]string, 0, attrSize)
+		for _, key := range sortedKeys(objAttrs) {
+			scope.AddToVars(objAttrs[key])
+			variables = append(variables, "?")
+		}
+
+		placeholders = append(placeholders, "("+strings.Join(variables, ", ")+")")
+		mainScope.SQLVars = append(mainScope.SQLVars, scope.SQLVars...)
+	}
+
+	target := conflictTarget
+	if len(target) == 0 {
+		if pk := mainScope.PrimaryField(); pk != nil {
+			target = []string{pk.DBName}
+		}
+	}
+
+	update := updateColumns
+	if len(update) == 0 {
+		update = defaultUpdateColumns(keys, target)
+	}
+
+	onConflict, err := buildUpsertClause(db.Dialect().GetName(), mainScope, target, update)
+	if err != nil {
+		return 0, err
+	}
+
+	mainScope.Raw(fmt.Sprintf("INSERT INTO %s (%s) VALUES %s %s",
+		mainScope.QuotedTableName(),
+		strings.Join(dbColumns, ", "),
+		strings.Join(placeholders, ", "),
+		onConflict,
+	))
+
+	result := db.Exec(mainScope.SQL, mainScope.SQLVars...)
+	return result.RowsAffected, result.Error
+}
+
+// defaultUpdateColumns returns every inserted column except those in
+// conflictTarget, used when the caller doesn't supply an explicit
+// updateColumns list. conflictTarget may be a composite key, so membership is
+// checked against the whole list rather than assuming a single column.
+func defaultUpdateColumns(keys, conflictTarget []string) []string {
+	var update []string
+	for _, key := range keys {
+		if containString(conflictTarget, key) {
+			continue
+		}
+		update = append(update, key)
+	}
+	return update
+}
+
+// buildUpsertClause builds the dialect-specific suffix that turns a plain INSERT
+// into an upsert, e.g. "ON DUPLICATE KEY UPDATE ..." or "ON CONFLICT (...) DO UPDATE SET ...".
+func buildUpsertClause(dialect string, scope *gorm.Scope, conflictTarget []string, updateColumns []string) (string, error) {
+	switch dialect {
+	case "mysql":
+		assignments := make([]string, 0, len(updateColumns))
+		for _, col := range updateColumns {
+			quoted := scope.Quote(col)
+			assignments = append(assignments, fmt.Sprintf("%s=VALUES(%s)", quoted, quoted))
+		}
+		return "ON DUPLICATE KEY UPDATE " + strings.Join(assignments, ", "), nil
+	case "postgres", "sqlite3":
+		if len(conflictTarget) == 0 {
+			return "", errors.New("gormbulk: could not determine a conflict target, the primary key is unknown and none was supplied")
+		}
+		targets := make([]string, 0, len(conflictTarget))
+		for _, col := range conflictTarget {
+			targets = append(targets, scope.Quote(col))
+		}
+		assignments := make([]string, 0, len(updateColumns))
+		for _, col := range updateColumns {
+			quoted := scope.Quote(col)
+			assignments = append(assignments, fmt.Sprintf("%s=EXCLUDED.%s", quoted, quoted))
+		}
+		return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s", strings.Join(targets, ", "), strings.Join(assignments, ", ")), nil
+	default:
+		return "", fmt.Errorf("gormbulk: BulkUpsert does not support dialect %q", dialect)
+	}
+}
@@ -0,0 +1,73 @@
+package gormbulk
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDefaultUpdateColumns_ExcludesCompositeConflictTarget(t *testing.T) {
+	got := defaultUpdateColumns([]string{"id", "tenant_id", "name", "age"}, []string{"id", "tenant_id"})
+	want := []string{"name", "age"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("defaultUpdateColumns() = %v, want %v", got, want)
+	}
+}
+
+func TestDefaultUpdateColumns_SingleColumnTarget(t *testing.T) {
+	got := defaultUpdateColumns([]string{"id", "name", "age"}, []string{"id"})
+	want := []string{"name", "age"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("defaultUpdateColumns() = %v, want %v", got, want)
+	}
+}
+
+func TestBuildUpsertClause_MySQL(t *testing.T) {
+	db := newTestDB(t)
+	scope := db.NewScope(&bulkTestRecord{})
+
+	clause, err := buildUpsertClause("mysql", scope, nil, []string{"name", "age"})
+	if err != nil {
+		t.Fatalf("buildUpsertClause: %v", err)
+	}
+	if !strings.HasPrefix(clause, "ON DUPLICATE KEY UPDATE") {
+		t.Errorf("unexpected clause: %q", clause)
+	}
+	if !strings.Contains(clause, "VALUES(") {
+		t.Errorf("expected VALUES() references, got %q", clause)
+	}
+}
+
+func TestBuildUpsertClause_Postgres(t *testing.T) {
+	db := newTestDB(t)
+	scope := db.NewScope(&bulkTestRecord{})
+
+	clause, err := buildUpsertClause("postgres", scope, []string{"id"}, []string{"name", "age"})
+	if err != nil {
+		t.Fatalf("buildUpsertClause: %v", err)
+	}
+	if !strings.Contains(clause, "ON CONFLICT (") || !strings.Contains(clause, "DO UPDATE SET") {
+		t.Errorf("unexpected clause: %q", clause)
+	}
+	if !strings.Contains(clause, "EXCLUDED.") {
+		t.Errorf("expected EXCLUDED references, got %q", clause)
+	}
+}
+
+func TestBuildUpsertClause_PostgresMissingConflictTarget(t *testing.T) {
+	db := newTestDB(t)
+	scope := db.NewScope(&bulkTestRecord{})
+
+	if _, err := buildUpsertClause("postgres", scope, nil, []string{"name"}); err == nil {
+		t.Fatal("expected an error when no conflict target is known")
+	}
+}
+
+func TestBuildUpsertClause_UnsupportedDialect(t *testing.T) {
+	db := newTestDB(t)
+	scope := db.NewScope(&bulkTestRecord{})
+
+	if _, err := buildUpsertClause("oracle", scope, []string{"id"}, []string{"name"}); err == nil {
+		t.Fatal("expected an error for an unsupported dialect")
+	}
+}
@@ -0,0 +1,102 @@
+package gormbulk
+
+import "github.com/jinzhu/gorm"
+
+// BeforeBulkInserter is implemented by structs that need to run logic right
+// before they are written as part of a bulk insert. BulkInsertWithOptions
+// calls it for every object in a chunk before that chunk is written; an error
+// aborts the chunk.
+type BeforeBulkInserter interface {
+	BeforeBulkInsert(scope *gorm.Scope) error
+}
+
+// AfterBulkInserter is implemented by structs that need to run logic right
+// after they were written as part of a bulk insert. BulkInsertWithOptions
+// calls it for every object in a chunk once that chunk has been written
+// successfully.
+type AfterBulkInserter interface {
+	AfterBulkInsert(scope *gorm.Scope) error
+}
+
+// BulkInsertOptions controls the optional hook behavior of
+// BulkInsertWithOptions.
+type BulkInsertOptions struct {
+	// RunCallbacks additionally invokes the BeforeSave/BeforeCreate struct
+	// methods gorm itself would call before an INSERT, and AfterCreate/AfterSave
+	// after it, for every object. It does not run gorm's registered create
+	// callback chain (db.Callback().Create()) or save associations: doing so
+	// would run gorm's own INSERT for a row this package already wrote as part
+	// of the bulk statement, and would touch db's globally-registered callback
+	// chain, which is shared with any other goroutine using the same *gorm.DB.
+	// Off by default, since it adds a per-row cost that plain BulkInsert avoids.
+	RunCallbacks bool
+}
+
+// BulkInsertWithOptions behaves like BulkInsert, but additionally invokes
+// hooks around each chunk: objects implementing BeforeBulkInserter /
+// AfterBulkInserter have those methods called, and, when opts.RunCallbacks is
+// set, any BeforeSave/BeforeCreate/AfterCreate/AfterSave methods on the
+// object are called too. BulkInsert itself bypasses all of this and only
+// special-cases CreatedAt/UpdatedAt inline.
+func BulkInsertWithOptions(db *gorm.DB, objects []interface{}, chunkSize int, opts BulkInsertOptions, excludeColumns ...string) error {
+	for _, objSet := range splitObjects(objects, chunkSize) {
+		if err := runBeforeBulkInsertHooks(db, objSet, opts); err != nil {
+			return err
+		}
+		if _, err := insertObjSet(db, nil, objSet, nil, excludeColumns...); err != nil {
+			return err
+		}
+		if err := runAfterBulkInsertHooks(db, objSet, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runBeforeBulkInsertHooks(db *gorm.DB, objects []interface{}, opts BulkInsertOptions) error {
+	for _, obj := range objects {
+		if hook, ok := obj.(BeforeBulkInserter); ok {
+			if err := hook.BeforeBulkInsert(db.NewScope(obj)); err != nil {
+				return err
+			}
+		}
+		if opts.RunCallbacks {
+			if err := callMethods(db, obj, "BeforeSave", "BeforeCreate"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func runAfterBulkInsertHooks(db *gorm.DB, objects []interface{}, opts BulkInsertOptions) error {
+	for _, obj := range objects {
+		if opts.RunCallbacks {
+			if err := callMethods(db, obj, "AfterCreate", "AfterSave"); err != nil {
+				return err
+			}
+		}
+		if hook, ok := obj.(AfterBulkInserter); ok {
+			if err := hook.AfterBulkInsert(db.NewScope(obj)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// callMethods invokes each of the named gorm lifecycle methods (e.g.
+// "BeforeCreate", "AfterSave") on obj, in order, the same way gorm's own
+// create callback chain does via scope.CallMethod - calling a method only if
+// obj actually implements it, under any of the signatures gorm supports. It
+// stops and returns the first error encountered.
+func callMethods(db *gorm.DB, obj interface{}, methods ...string) error {
+	scope := db.NewScope(obj)
+	for _, method := range methods {
+		scope.CallMethod(method)
+		if scope.HasError() {
+			return scope.DB().Error
+		}
+	}
+	return nil
+}
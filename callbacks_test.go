@@ -0,0 +1,69 @@
+package gormbulk
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+)
+
+type callbackOrderRecord struct {
+	ID    int64 `gorm:"primary_key"`
+	Name  string
+	order *[]string
+}
+
+func (callbackOrderRecord) TableName() string { return "bulk_test_records" }
+
+func (r *callbackOrderRecord) BeforeSave(*gorm.Scope) error {
+	*r.order = append(*r.order, "BeforeSave")
+	return nil
+}
+
+func (r *callbackOrderRecord) BeforeCreate(*gorm.Scope) error {
+	*r.order = append(*r.order, "BeforeCreate")
+	return nil
+}
+
+func (r *callbackOrderRecord) AfterCreate(*gorm.Scope) error {
+	*r.order = append(*r.order, "AfterCreate")
+	return nil
+}
+
+func (r *callbackOrderRecord) AfterSave(*gorm.Scope) error {
+	*r.order = append(*r.order, "AfterSave")
+	return nil
+}
+
+func TestBulkInsertWithOptions_RunCallbacksInvocationOrder(t *testing.T) {
+	db := newTestDB(t)
+
+	var order []string
+	obj := &callbackOrderRecord{ID: 1, Name: "a", order: &order}
+
+	err := BulkInsertWithOptions(db, []interface{}{obj}, 10, BulkInsertOptions{RunCallbacks: true})
+	if err != nil {
+		t.Fatalf("BulkInsertWithOptions: %v", err)
+	}
+
+	want := []string{"BeforeSave", "BeforeCreate", "AfterCreate", "AfterSave"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("callback order = %v, want %v", order, want)
+	}
+}
+
+func TestBulkInsertWithOptions_RunCallbacksOffByDefault(t *testing.T) {
+	db := newTestDB(t)
+
+	var order []string
+	obj := &callbackOrderRecord{ID: 1, Name: "a", order: &order}
+
+	err := BulkInsertWithOptions(db, []interface{}{obj}, 10, BulkInsertOptions{})
+	if err != nil {
+		t.Fatalf("BulkInsertWithOptions: %v", err)
+	}
+
+	if len(order) != 0 {
+		t.Errorf("expected no lifecycle methods to run, got %v", order)
+	}
+}
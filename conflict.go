@@ -0,0 +1,115 @@
+package gormbulk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jinzhu/gorm"
+)
+
+// ConflictStrategy builds the dialect-specific SQL needed to make a bulk INSERT
+// silently skip rows that conflict with an existing record. Each gorm dialect
+// needs a different incantation, so BulkInsertIgnore resolves one from
+// db.Dialect().GetName() instead of hard-coding the SQL inline.
+type ConflictStrategy interface {
+	// Build returns the clause(s) needed to ignore conflicts on an INSERT into
+	// the table described by scope, given the already-quoted column list and
+	// value placeholders that make up the VALUES clause. conflictTarget is the
+	// optional column list the caller wants the conflict checked against; it
+	// only matters for dialects whose syntax takes one (PostgreSQL, where it is
+	// optional, and SQL Server, where it is required).
+	Build(scope *gorm.Scope, dbColumns, placeholders, conflictTarget []string) (conflictClause, error)
+}
+
+// conflictClause describes how to turn a plain INSERT into one that ignores
+// conflicts. Either Modifier/Suffix are spliced into the regular
+// "INSERT <Modifier> INTO table (...) VALUES (...) <Suffix>" statement, or, for
+// dialects whose ignore-on-conflict doesn't fit that shape, Statement holds a
+// complete, ready-to-run statement instead.
+type conflictClause struct {
+	Modifier  string
+	Suffix    string
+	Statement string
+}
+
+// conflictStrategyFor resolves the ConflictStrategy for a gorm dialect name, as
+// returned by db.Dialect().GetName().
+func conflictStrategyFor(dialect string) (ConflictStrategy, error) {
+	switch dialect {
+	case "mysql":
+		return mysqlConflictStrategy{}, nil
+	case "postgres":
+		return postgresConflictStrategy{}, nil
+	case "sqlite3":
+		return sqliteConflictStrategy{}, nil
+	case "mssql":
+		return sqlserverConflictStrategy{}, nil
+	default:
+		return nil, fmt.Errorf("gormbulk: BulkInsertIgnore does not support dialect %q", dialect)
+	}
+}
+
+// mysqlConflictStrategy uses MySQL's INSERT IGNORE, which skips any row that
+// would violate a unique or primary key constraint.
+type mysqlConflictStrategy struct{}
+
+func (mysqlConflictStrategy) Build(scope *gorm.Scope, dbColumns, placeholders, conflictTarget []string) (conflictClause, error) {
+	return conflictClause{Modifier: "IGNORE"}, nil
+}
+
+// postgresConflictStrategy uses ON CONFLICT ... DO NOTHING. Unlike MySQL,
+// PostgreSQL requires the statement to actually parse as valid ON CONFLICT
+// syntax - "ON CONFLICT IGNORE" (what this package used to emit) is not valid SQL.
+type postgresConflictStrategy struct{}
+
+func (postgresConflictStrategy) Build(scope *gorm.Scope, dbColumns, placeholders, conflictTarget []string) (conflictClause, error) {
+	if len(conflictTarget) == 0 {
+		return conflictClause{Suffix: "ON CONFLICT DO NOTHING"}, nil
+	}
+	targets := make([]string, 0, len(conflictTarget))
+	for _, col := range conflictTarget {
+		targets = append(targets, scope.Quote(col))
+	}
+	return conflictClause{Suffix: fmt.Sprintf("ON CONFLICT (%s) DO NOTHING", strings.Join(targets, ", "))}, nil
+}
+
+// sqliteConflictStrategy uses SQLite's INSERT OR IGNORE.
+type sqliteConflictStrategy struct{}
+
+func (sqliteConflictStrategy) Build(scope *gorm.Scope, dbColumns, placeholders, conflictTarget []string) (conflictClause, error) {
+	return conflictClause{Modifier: "OR IGNORE"}, nil
+}
+
+// sqlserverConflictStrategy has no INSERT ... IGNORE equivalent, so it rebuilds
+// the statement as a MERGE that only inserts rows not already present, keyed on
+// conflictTarget (required, since MERGE has no implicit conflict target).
+type sqlserverConflictStrategy struct{}
+
+func (sqlserverConflictStrategy) Build(scope *gorm.Scope, dbColumns, placeholders, conflictTarget []string) (conflictClause, error) {
+	if len(conflictTarget) == 0 {
+		return conflictClause{}, fmt.Errorf("gormbulk: BulkInsertIgnore on mssql requires an explicit conflict target column list")
+	}
+
+	on := make([]string, 0, len(conflictTarget))
+	for _, col := range conflictTarget {
+		quoted := scope.Quote(col)
+		on = append(on, fmt.Sprintf("target.%s = source.%s", quoted, quoted))
+	}
+
+	columns := strings.Join(dbColumns, ", ")
+	sourceColumns := make([]string, 0, len(dbColumns))
+	for _, col := range dbColumns {
+		sourceColumns = append(sourceColumns, "source."+col)
+	}
+
+	statement := fmt.Sprintf(
+		"MERGE INTO %s AS target USING (VALUES %s) AS source (%s) ON %s WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s);",
+		scope.QuotedTableName(),
+		strings.Join(placeholders, ", "),
+		columns,
+		strings.Join(on, " AND "),
+		columns,
+		strings.Join(sourceColumns, ", "),
+	)
+	return conflictClause{Statement: statement}, nil
+}
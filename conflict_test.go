@@ -0,0 +1,116 @@
+package gormbulk
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConflictStrategyFor_UnknownDialect(t *testing.T) {
+	if _, err := conflictStrategyFor("oracle"); err == nil {
+		t.Fatal("expected an error for an unsupported dialect, got nil")
+	}
+}
+
+func TestMySQLConflictStrategy_Build(t *testing.T) {
+	db := newTestDB(t)
+	scope := db.NewScope(&bulkTestRecord{})
+
+	strategy, err := conflictStrategyFor("mysql")
+	if err != nil {
+		t.Fatalf("conflictStrategyFor: %v", err)
+	}
+
+	clause, err := strategy.Build(scope, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if clause.Modifier != "IGNORE" {
+		t.Errorf("expected modifier IGNORE, got %q", clause.Modifier)
+	}
+	if clause.Suffix != "" || clause.Statement != "" {
+		t.Errorf("mysql strategy should only set Modifier, got %+v", clause)
+	}
+}
+
+func TestPostgresConflictStrategy_Build(t *testing.T) {
+	db := newTestDB(t)
+	scope := db.NewScope(&bulkTestRecord{})
+
+	strategy, err := conflictStrategyFor("postgres")
+	if err != nil {
+		t.Fatalf("conflictStrategyFor: %v", err)
+	}
+
+	t.Run("no conflict target", func(t *testing.T) {
+		clause, err := strategy.Build(scope, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		if clause.Suffix != "ON CONFLICT DO NOTHING" {
+			t.Errorf("unexpected suffix: %q", clause.Suffix)
+		}
+	})
+
+	t.Run("with conflict target", func(t *testing.T) {
+		clause, err := strategy.Build(scope, nil, nil, []string{"name"})
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		if !strings.Contains(clause.Suffix, "ON CONFLICT (") || !strings.Contains(clause.Suffix, "DO NOTHING") {
+			t.Errorf("unexpected suffix: %q", clause.Suffix)
+		}
+	})
+}
+
+func TestSQLiteConflictStrategy_Build(t *testing.T) {
+	db := newTestDB(t)
+	scope := db.NewScope(&bulkTestRecord{})
+
+	strategy, err := conflictStrategyFor("sqlite3")
+	if err != nil {
+		t.Fatalf("conflictStrategyFor: %v", err)
+	}
+
+	clause, err := strategy.Build(scope, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if clause.Modifier != "OR IGNORE" {
+		t.Errorf("expected modifier OR IGNORE, got %q", clause.Modifier)
+	}
+}
+
+func TestSQLServerConflictStrategy_Build(t *testing.T) {
+	db := newTestDB(t)
+	scope := db.NewScope(&bulkTestRecord{})
+
+	strategy, err := conflictStrategyFor("mssql")
+	if err != nil {
+		t.Fatalf("conflictStrategyFor: %v", err)
+	}
+
+	t.Run("missing conflict target errors", func(t *testing.T) {
+		if _, err := strategy.Build(scope, nil, nil, nil); err == nil {
+			t.Fatal("expected an error when no conflict target is given")
+		}
+	})
+
+	t.Run("builds a MERGE statement", func(t *testing.T) {
+		dbColumns := []string{"name", "age"}
+		placeholders := []string{"(?, ?)", "(?, ?)"}
+
+		clause, err := strategy.Build(scope, dbColumns, placeholders, []string{"name"})
+		if err != nil {
+			t.Fatalf("Build: %v", err)
+		}
+		if !strings.Contains(clause.Statement, "MERGE INTO") {
+			t.Errorf("expected a MERGE statement, got %q", clause.Statement)
+		}
+		if !strings.Contains(clause.Statement, "WHEN NOT MATCHED THEN INSERT") {
+			t.Errorf("expected an INSERT branch, got %q", clause.Statement)
+		}
+		if !strings.Contains(clause.Statement, "source.name") {
+			t.Errorf("expected source columns to be referenced, got %q", clause.Statement)
+		}
+	})
+}
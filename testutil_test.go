@@ -0,0 +1,38 @@
+package gormbulk
+
+import (
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+type bulkTestRecord struct {
+	ID   int64 `gorm:"primary_key"`
+	Name string
+	Age  int
+}
+
+func (bulkTestRecord) TableName() string {
+	return "bulk_test_records"
+}
+
+// newTestDB opens an in-memory SQLite database migrated for bulkTestRecord.
+// scope.Quote's output will reflect SQLite's quoting rules regardless of
+// which dialect name a test passes to functions like buildUpsertClause, so
+// tests assert on SQL keywords and shape rather than the exact quote
+// characters used.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.AutoMigrate(&bulkTestRecord{}).Error; err != nil {
+		t.Fatalf("failed to migrate test schema: %v", err)
+	}
+	return db
+}